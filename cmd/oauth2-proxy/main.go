@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/oauth2-proxy/oauth2-proxy/pkg/allowlist"
+	"github.com/oauth2-proxy/oauth2-proxy/pkg/apis/options"
+	"github.com/oauth2-proxy/oauth2-proxy/pkg/validation"
+)
+
+// pollInterval is how often the allowlist config file is re-read for
+// changes in addition to reacting to SIGHUP, so edits are picked up even
+// when the process isn't (or can't be) signaled directly.
+const pollInterval = 5 * time.Second
+
+func main() {
+	configFile := flag.String("config", "", "path to the allowlist config file (JSON)")
+	flag.Parse()
+
+	if *configFile == "" {
+		log.Fatal("--config is required")
+	}
+
+	allowers, msgs := loadAllowlist(*configFile)
+	if len(msgs) > 0 {
+		for _, msg := range msgs {
+			log.Printf("  - %s", msg)
+		}
+		log.Fatalf("%s failed validation", *configFile)
+	}
+
+	reloader := allowlist.NewReloader(allowers)
+
+	stop := make(chan struct{})
+	go reloader.Watch(func() ([]allowlist.Allower, []string) {
+		allowers, msgs := loadAllowlist(*configFile)
+		if len(msgs) > 0 {
+			logRejectedReload(*configFile, msgs)
+			return nil, msgs
+		}
+		log.Printf("allowlist: reloaded %s", *configFile)
+		return allowers, msgs
+	}, pollInterval, stop, nil)
+	defer close(stop)
+
+	waitForShutdown()
+}
+
+// loadAllowlist reads and validates the allowlist config at path, returning
+// the Allower set it describes together with any validation error messages.
+// allowers is nil whenever msgs is non-empty.
+func loadAllowlist(path string) ([]allowlist.Allower, []string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("could not read config %s: %v", path, err)}
+	}
+
+	opts := &options.Options{}
+	if err := json.Unmarshal(data, opts); err != nil {
+		return nil, []string{fmt.Sprintf("could not parse config %s: %v", path, err)}
+	}
+
+	allowers, msgs := validation.ValidateAllowlist(opts)
+	if len(msgs) > 0 {
+		return nil, msgs
+	}
+	return allowers, nil
+}
+
+// logRejectedReload logs why a (re)load of path was rejected, so an operator
+// watching process logs can see a bad edit without the process losing its
+// previously validated allowlist.
+func logRejectedReload(path string, msgs []string) {
+	log.Printf("allowlist: keeping previous config, %s failed validation:", path)
+	for _, msg := range msgs {
+		log.Printf("  - %s", msg)
+	}
+}
+
+// waitForShutdown blocks until the process receives SIGINT/SIGTERM.
+func waitForShutdown() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	<-sig
+}
@@ -0,0 +1,12 @@
+// Package allowlist provides the rules used to decide whether an incoming
+// request should bypass authentication entirely (for example health checks,
+// CORS preflight requests, or requests originating from a trusted network).
+package allowlist
+
+import "net/http"
+
+// Allower is implemented by every allowlist rule. IsTrusted reports whether
+// the given request satisfies that rule and should therefore skip auth.
+type Allower interface {
+	IsTrusted(req *http.Request) bool
+}
@@ -0,0 +1,42 @@
+package allowlist
+
+import (
+	"net/http"
+	"regexp"
+)
+
+// headerMatcher trusts requests whose named header matches a regex.
+type headerMatcher struct {
+	header string
+	regex  *regexp.Regexp
+}
+
+// Headers is an Allower that trusts requests carrying a header whose value
+// matches one of the configured rules, as configured via SkipAuthHeaders.
+type Headers struct {
+	matchers []headerMatcher
+}
+
+// NewHeaders constructs an empty Headers allowlist, to be populated via
+// AddHeader.
+func NewHeaders() *Headers {
+	return &Headers{}
+}
+
+// AddHeader registers a header/regex pair with the Headers allowlist.
+func (h *Headers) AddHeader(header string, regex *regexp.Regexp) {
+	h.matchers = append(h.matchers, headerMatcher{header: header, regex: regex})
+}
+
+// IsTrusted returns whether req carries a header matching one of the
+// configured rules.
+func (h *Headers) IsTrusted(req *http.Request) bool {
+	for _, m := range h.matchers {
+		for _, value := range req.Header.Values(m.header) {
+			if m.regex.MatchString(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
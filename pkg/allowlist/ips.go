@@ -0,0 +1,139 @@
+package allowlist
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIPResolver determines the client IP to use when evaluating an IPs
+// allowlist. When a header is configured, the resolver only trusts it for
+// requests arriving directly from one of trustedProxies - otherwise any
+// client could simply set the header itself to spoof its way past the
+// allowlist.
+type ClientIPResolver struct {
+	header         string
+	trustedProxies []*net.IPNet
+}
+
+// NewClientIPResolver constructs a ClientIPResolver. An empty header means
+// the resolver always uses req.RemoteAddr.
+func NewClientIPResolver(header string, trustedProxies []*net.IPNet) *ClientIPResolver {
+	return &ClientIPResolver{header: header, trustedProxies: trustedProxies}
+}
+
+// Resolve returns the best-effort client IP for req: the rightmost
+// non-trusted-proxy address in the configured header when req arrives from a
+// trusted proxy, falling back to X-Real-Ip and finally req.RemoteAddr.
+func (r *ClientIPResolver) Resolve(req *http.Request) net.IP {
+	remoteIP := remoteAddrIP(req)
+	if r == nil || r.header == "" || !r.isTrustedProxy(remoteIP) {
+		return remoteIP
+	}
+	if ip := r.resolveForwardedFor(req); ip != nil {
+		return ip
+	}
+	if realIP := net.ParseIP(req.Header.Get("X-Real-Ip")); realIP != nil {
+		return realIP
+	}
+	return remoteIP
+}
+
+// resolveForwardedFor walks the configured header from right to left,
+// skipping any hop that is itself one of the trusted proxies, and returns
+// the first address that isn't.
+func (r *ClientIPResolver) resolveForwardedFor(req *http.Request) net.IP {
+	raw := req.Header.Get(r.header)
+	if raw == "" {
+		return nil
+	}
+	hops := strings.Split(raw, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		candidate := net.ParseIP(strings.TrimSpace(hops[i]))
+		if candidate == nil || r.isTrustedProxy(candidate) {
+			continue
+		}
+		return candidate
+	}
+	return nil
+}
+
+func (r *ClientIPResolver) isTrustedProxy(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, proxyNet := range r.trustedProxies {
+		if proxyNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteAddrIP(req *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+// IPs is an Allower that trusts requests whose resolved client IP falls
+// within a configured set of IPs/CIDRs, as configured via TrustedIPs.
+type IPs struct {
+	ipNets   []*net.IPNet
+	resolver *ClientIPResolver
+}
+
+// NewIPs creates an empty IPs allowlist, to be populated via AddIPNet. The
+// given resolver determines how the client IP is extracted from each
+// request; a nil resolver uses req.RemoteAddr directly.
+func NewIPs(resolver *ClientIPResolver) *IPs {
+	return &IPs{resolver: resolver}
+}
+
+// AddIPNet registers an IP network with the IPs allowlist.
+func (i *IPs) AddIPNet(ipNet *net.IPNet) {
+	i.ipNets = append(i.ipNets, ipNet)
+}
+
+// IsTrusted returns whether req's resolved client IP falls within one of the
+// configured IP networks.
+func (i *IPs) IsTrusted(req *http.Request) bool {
+	ip := i.clientIP(req)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range i.ipNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *IPs) clientIP(req *http.Request) net.IP {
+	if i.resolver != nil {
+		return i.resolver.Resolve(req)
+	}
+	return remoteAddrIP(req)
+}
+
+// ParseIPNet parses a bare IP or CIDR string into a *net.IPNet, treating a
+// bare IP as an exact match (a /32 for IPv4, /128 for IPv6).
+func ParseIPNet(entry string) (*net.IPNet, error) {
+	if !strings.Contains(entry, "/") {
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP: %s", entry)
+		}
+		bits := 32
+		if ip.To4() == nil {
+			bits = 128
+		}
+		entry = fmt.Sprintf("%s/%d", entry, bits)
+	}
+	_, ipNet, err := net.ParseCIDR(entry)
+	return ipNet, err
+}
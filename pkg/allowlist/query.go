@@ -0,0 +1,43 @@
+package allowlist
+
+import (
+	"net/http"
+	"regexp"
+)
+
+// queryMatcher trusts requests whose named query parameter matches a regex.
+type queryMatcher struct {
+	key   string
+	regex *regexp.Regexp
+}
+
+// Query is an Allower that trusts requests carrying a query parameter whose
+// value matches one of the configured rules, as configured via
+// SkipAuthQuery.
+type Query struct {
+	matchers []queryMatcher
+}
+
+// NewQuery constructs an empty Query allowlist, to be populated via AddQuery.
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// AddQuery registers a key/regex pair with the Query allowlist.
+func (q *Query) AddQuery(key string, regex *regexp.Regexp) {
+	q.matchers = append(q.matchers, queryMatcher{key: key, regex: regex})
+}
+
+// IsTrusted returns whether req carries a query parameter matching one of
+// the configured rules.
+func (q *Query) IsTrusted(req *http.Request) bool {
+	values := req.URL.Query()
+	for _, m := range q.matchers {
+		for _, value := range values[m.key] {
+			if m.regex.MatchString(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package allowlist
+
+import (
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Reloader holds an atomically-swappable set of Allower rules. It is itself
+// an Allower, so callers can wire it in wherever a single Routes/IPs/etc.
+// would otherwise go, then call Store to swap in a freshly (re)validated
+// set - e.g. on SIGHUP or a config file change - without blocking
+// in-flight IsTrusted calls. Every call to IsTrusted sees either the
+// previous set or the new one in its entirety, never a mix of the two.
+type Reloader struct {
+	allowers atomic.Value // []Allower
+}
+
+// NewReloader constructs a Reloader holding the given initial Allower set.
+func NewReloader(allowers []Allower) *Reloader {
+	r := &Reloader{}
+	r.Store(allowers)
+	return r
+}
+
+// Store atomically replaces the active Allower set.
+func (r *Reloader) Store(allowers []Allower) {
+	r.allowers.Store(allowers)
+}
+
+// IsTrusted returns whether req is trusted by any rule in the currently
+// active Allower set.
+func (r *Reloader) IsTrusted(req *http.Request) bool {
+	for _, allower := range r.allowers.Load().([]Allower) {
+		if allower.IsTrusted(req) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReloadFunc (re)loads and validates a configuration, returning the Allower
+// set it describes along with any validation error messages. A caller
+// (e.g. cmd/oauth2-proxy) typically implements this by reading its config
+// file and delegating to validation.ValidateAllowlist.
+type ReloadFunc func() (allowers []Allower, msgs []string)
+
+// Watch calls fn whenever the process receives SIGHUP, and additionally
+// every pollInterval if pollInterval > 0 (for callers that want to pick up
+// config file changes without relying on a signal). A result with no error
+// messages is swapped in via Store; a failed reload is logged nowhere by
+// Watch itself - it is the caller's responsibility to surface fn's error
+// messages, since Watch only knows how to keep the previous, known-good
+// Allower set in place. Watch blocks until stop is closed.
+//
+// ready, if non-nil, is closed once SIGHUP handling is registered and Watch
+// is about to start waiting for events. Callers that need to send Watch's
+// own process a SIGHUP deterministically (chiefly tests) should wait on
+// ready first, since signal.Notify only takes effect for signals received
+// after it runs. Production callers have no need to synchronize on this and
+// can pass nil.
+func (r *Reloader) Watch(fn ReloadFunc, pollInterval time.Duration, stop <-chan struct{}, ready chan<- struct{}) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	var tick <-chan time.Time
+	if pollInterval > 0 {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	if ready != nil {
+		close(ready)
+	}
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-sighup:
+			r.reload(fn)
+		case <-tick:
+			r.reload(fn)
+		}
+	}
+}
+
+func (r *Reloader) reload(fn ReloadFunc) {
+	if allowers, msgs := fn(); len(msgs) == 0 {
+		r.Store(allowers)
+	}
+}
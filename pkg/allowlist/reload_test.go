@@ -0,0 +1,105 @@
+package allowlist
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloader_HappyPathReload(t *testing.T) {
+	routesV1 := NewRoutes()
+	routesV1.AddRoute("", regexp.MustCompile("^/v1$"))
+	reloader := NewReloader([]Allower{routesV1})
+
+	v1Req := &http.Request{URL: &url.URL{Path: "/v1"}}
+	v2Req := &http.Request{URL: &url.URL{Path: "/v2"}}
+	assert.True(t, reloader.IsTrusted(v1Req))
+	assert.False(t, reloader.IsTrusted(v2Req))
+
+	routesV2 := NewRoutes()
+	routesV2.AddRoute("", regexp.MustCompile("^/v2$"))
+	reloader.Store([]Allower{routesV2})
+
+	assert.False(t, reloader.IsTrusted(v1Req))
+	assert.True(t, reloader.IsTrusted(v2Req))
+}
+
+func TestReloader_ConcurrentSwap(t *testing.T) {
+	routesV1 := NewRoutes()
+	routesV1.AddRoute("", regexp.MustCompile("^/v1$"))
+	reloader := NewReloader([]Allower{routesV1})
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		req := &http.Request{URL: &url.URL{Path: "/v1"}}
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				reloader.IsTrusted(req)
+			}
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		routes := NewRoutes()
+		routes.AddRoute("", regexp.MustCompile("^/v1$"))
+		reloader.Store([]Allower{routes})
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestReloader_WatchSIGHUP(t *testing.T) {
+	routesV1 := NewRoutes()
+	routesV1.AddRoute("", regexp.MustCompile("^/v1$"))
+	reloader := NewReloader([]Allower{routesV1})
+
+	routesV2 := NewRoutes()
+	routesV2.AddRoute("", regexp.MustCompile("^/v2$"))
+
+	stop := make(chan struct{})
+	defer close(stop)
+	ready := make(chan struct{})
+	go reloader.Watch(func() ([]Allower, []string) {
+		return []Allower{routesV2}, []string{}
+	}, 0, stop, ready)
+
+	require := assert.New(t)
+	<-ready
+	self, err := os.FindProcess(os.Getpid())
+	require.NoError(err)
+	require.NoError(self.Signal(syscall.SIGHUP))
+
+	assert.Eventually(t, func() bool {
+		return reloader.IsTrusted(&http.Request{URL: &url.URL{Path: "/v2"}})
+	}, time.Second, time.Millisecond)
+}
+
+func TestReloader_WatchRejectsBadReload(t *testing.T) {
+	routesV1 := NewRoutes()
+	routesV1.AddRoute("", regexp.MustCompile("^/v1$"))
+	reloader := NewReloader([]Allower{routesV1})
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go reloader.Watch(func() ([]Allower, []string) {
+		return nil, []string{"error compiling regex /(bad/: boom"}
+	}, time.Millisecond, stop, nil)
+
+	req := &http.Request{URL: &url.URL{Path: "/v1"}}
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, reloader.IsTrusted(req))
+}
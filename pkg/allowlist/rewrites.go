@@ -0,0 +1,100 @@
+package allowlist
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// RewritePathHeader is set to the original, pre-rewrite path whenever a
+// Rewrites rule fires, matching the common reverse-proxy convention of
+// preserving the original path for upstreams that want to log or route on
+// it.
+const RewritePathHeader = "X-Replaced-Path"
+
+// rewriteRule is a single SkipAuthRewrites entry: something that can decide
+// whether it applies to a request, and rewrite that request's path.
+type rewriteRule interface {
+	matches(req *http.Request) bool
+	rewrite(req *http.Request)
+}
+
+// methodRewrite rewrites req.URL.Path by regex match/replace, optionally
+// scoped to a single HTTP method.
+type methodRewrite struct {
+	method      string
+	regex       *regexp.Regexp
+	replacement string
+}
+
+func (m *methodRewrite) matches(req *http.Request) bool {
+	return (m.method == "" || m.method == req.Method) && m.regex.MatchString(req.URL.Path)
+}
+
+func (m *methodRewrite) rewrite(req *http.Request) {
+	req.URL.Path = m.regex.ReplaceAllString(req.URL.Path, m.replacement)
+}
+
+// stripRewrite removes a fixed prefix from req.URL.Path, e.g. "strip:/public/".
+type stripRewrite struct {
+	prefix string
+}
+
+func (s *stripRewrite) matches(req *http.Request) bool {
+	return strings.HasPrefix(req.URL.Path, s.prefix)
+}
+
+func (s *stripRewrite) rewrite(req *http.Request) {
+	req.URL.Path = strings.TrimPrefix(req.URL.Path, s.prefix)
+	if !strings.HasPrefix(req.URL.Path, "/") {
+		req.URL.Path = "/" + req.URL.Path
+	}
+}
+
+// Rewrites is an Allower that, like Routes, bypasses authentication for
+// matching requests, but also rewrites the request path before it is
+// forwarded upstream, as configured via SkipAuthRewrites.
+type Rewrites struct {
+	rules []rewriteRule
+}
+
+// NewRewrites constructs an empty Rewrites allowlist, to be populated via
+// AddMethodRewrite/AddStripRewrite.
+func NewRewrites() *Rewrites {
+	return &Rewrites{}
+}
+
+// AddMethodRewrite registers a regex match/replace rule, optionally scoped
+// to method. An empty method matches any HTTP method.
+func (r *Rewrites) AddMethodRewrite(method string, regex *regexp.Regexp, replacement string) {
+	r.rules = append(r.rules, &methodRewrite{method: method, regex: regex, replacement: replacement})
+}
+
+// AddStripRewrite registers a rule that removes prefix from the start of
+// the request path.
+func (r *Rewrites) AddStripRewrite(prefix string) {
+	r.rules = append(r.rules, &stripRewrite{prefix: prefix})
+}
+
+// IsTrusted returns whether req matches one of the configured rewrite rules.
+func (r *Rewrites) IsTrusted(req *http.Request) bool {
+	for _, rule := range r.rules {
+		if rule.matches(req) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rewrite applies the first matching rule to req, recording the original
+// path in RewritePathHeader so upstreams can still log or route on it.
+func (r *Rewrites) Rewrite(req *http.Request) {
+	for _, rule := range r.rules {
+		if rule.matches(req) {
+			original := req.URL.Path
+			rule.rewrite(req)
+			req.Header.Set(RewritePathHeader, original)
+			return
+		}
+	}
+}
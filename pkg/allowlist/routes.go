@@ -0,0 +1,52 @@
+package allowlist
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// route pairs an optional HTTP method with the regex its path must match.
+// An empty method matches requests of any method.
+type route struct {
+	method string
+	regex  *regexp.Regexp
+}
+
+// Routes is an Allower that trusts requests matching any of a set of
+// method+path rules, as configured via SkipAuthRoutes/SkipAuthRegex.
+type Routes struct {
+	routes []route
+}
+
+// NewRoutes constructs an empty Routes allowlist, to be populated via
+// AddRoute.
+func NewRoutes() *Routes {
+	return &Routes{}
+}
+
+// AddRoute registers a rule with the Routes allowlist. An empty method
+// matches any HTTP method.
+func (r *Routes) AddRoute(method string, regex *regexp.Regexp) {
+	r.routes = append(r.routes, route{method: method, regex: regex})
+}
+
+// IsTrusted returns whether req matches one of the configured routes.
+func (r *Routes) IsTrusted(req *http.Request) bool {
+	for _, rt := range r.routes {
+		if (rt.method == "" || rt.method == req.Method) && rt.regex.MatchString(req.URL.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseRoute splits a "METHOD=regex" entry into its method and regex pattern.
+// Entries with no "METHOD=" prefix match any HTTP method.
+func ParseRoute(entry string) (method string, pattern string) {
+	parts := strings.SplitN(entry, "=", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return "", entry
+}
@@ -0,0 +1,64 @@
+package allowlist
+
+import (
+	"net"
+	"net/http"
+	"regexp"
+)
+
+// routeFromIPs pairs a source IP network with a method+path rule; both must
+// match for the rule to trust a request.
+type routeFromIPs struct {
+	ipNet  *net.IPNet
+	method string
+	regex  *regexp.Regexp
+}
+
+// RoutesFromIPs is an Allower that trusts a request only when it matches
+// both a method+path rule and a source-IP CIDR, as configured via
+// SkipAuthRoutesFromIPs. This is the intersection of Routes and IPs: a rule
+// in Routes alone bypasses auth for everyone, and a rule in IPs alone
+// bypasses auth for every path - RoutesFromIPs requires both.
+type RoutesFromIPs struct {
+	rules    []routeFromIPs
+	resolver *ClientIPResolver
+}
+
+// NewRoutesFromIPs constructs an empty RoutesFromIPs allowlist, to be
+// populated via AddRoute. The given resolver determines how the client IP
+// is extracted from each request; a nil resolver uses req.RemoteAddr
+// directly.
+func NewRoutesFromIPs(resolver *ClientIPResolver) *RoutesFromIPs {
+	return &RoutesFromIPs{resolver: resolver}
+}
+
+// AddRoute registers a CIDR/method/regex rule with the RoutesFromIPs
+// allowlist. An empty method matches any HTTP method.
+func (r *RoutesFromIPs) AddRoute(ipNet *net.IPNet, method string, regex *regexp.Regexp) {
+	r.rules = append(r.rules, routeFromIPs{ipNet: ipNet, method: method, regex: regex})
+}
+
+// IsTrusted returns whether req's path/method and resolved client IP both
+// match one of the configured rules.
+func (r *RoutesFromIPs) IsTrusted(req *http.Request) bool {
+	ip := r.clientIP(req)
+	if ip == nil {
+		return false
+	}
+	for _, rule := range r.rules {
+		if !rule.ipNet.Contains(ip) {
+			continue
+		}
+		if (rule.method == "" || rule.method == req.Method) && rule.regex.MatchString(req.URL.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RoutesFromIPs) clientIP(req *http.Request) net.IP {
+	if r.resolver != nil {
+		return r.resolver.Resolve(req)
+	}
+	return remoteAddrIP(req)
+}
@@ -0,0 +1,167 @@
+package options
+
+import (
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/oauth2-proxy/oauth2-proxy/pkg/allowlist"
+)
+
+// Allowlist holds the configuration for requests that should bypass
+// authentication entirely.
+type Allowlist struct {
+	// SkipAuthRoutes bypasses authentication for requests matching one of
+	// these "METHOD=regex" (or bare regex, matching any method) patterns.
+	SkipAuthRoutes []string `json:"skipAuthRoutes,omitempty"`
+
+	// SkipAuthRegex bypasses authentication for requests whose path matches
+	// one of these regular expressions, regardless of method.
+	SkipAuthRegex []string `json:"skipAuthRegex,omitempty"`
+
+	// SkipAuthPreflight bypasses authentication for HTTP OPTIONS requests.
+	SkipAuthPreflight bool `json:"skipAuthPreflight,omitempty"`
+
+	// TrustedIPs bypasses authentication for requests whose client IP
+	// falls within one of these IPs/CIDRs.
+	TrustedIPs []string `json:"trustedIPs,omitempty"`
+
+	// TrustedIPsHeader names the header (e.g. X-Forwarded-For) that should
+	// be trusted to carry the original client IP for TrustedIPs, for
+	// requests arriving from one of TrustedIPsTrustedProxies. It is rejected
+	// unless at least one trusted proxy CIDR is configured, since trusting
+	// it otherwise would let any client spoof its way past TrustedIPs.
+	TrustedIPsHeader string `json:"trustedIPsHeader,omitempty"`
+
+	// TrustedIPsTrustedProxies lists the CIDRs of upstream proxies/load
+	// balancers allowed to set TrustedIPsHeader.
+	TrustedIPsTrustedProxies []string `json:"trustedIPsTrustedProxies,omitempty"`
+
+	// SkipAuthRewrites bypasses authentication for requests matching one of
+	// these rules, and rewrites the request path before it is forwarded
+	// upstream. Each entry is either "strip:<prefix>", which removes prefix
+	// from the path, or "[METHOD=]regex -> replacement", which rewrites the
+	// path using regex match/replace (replacement may reference capture
+	// groups, e.g. "$1").
+	SkipAuthRewrites []string `json:"skipAuthRewrites,omitempty"`
+
+	// SkipAuthHeaders bypasses authentication for requests carrying a header
+	// matching one of these "Header=regex" rules.
+	SkipAuthHeaders []string `json:"skipAuthHeaders,omitempty"`
+
+	// SkipAuthQuery bypasses authentication for requests carrying a query
+	// parameter matching one of these "key=regex" rules.
+	SkipAuthQuery []string `json:"skipAuthQuery,omitempty"`
+
+	// SkipAuthRoutesFromIPs bypasses authentication only when a request
+	// matches both a method+path rule and a source-IP CIDR, combining
+	// SkipAuthRoutes and TrustedIPs into a single rule. Each entry has the
+	// form "<CIDR>|[METHOD=]regex", e.g. "10.0.0.0/8|GET=/metrics".
+	SkipAuthRoutesFromIPs []string `json:"skipAuthRoutesFromIPs,omitempty"`
+}
+
+// GetAllowlists constructs the allowlist.Allower rules described by this
+// configuration. Entries that fail to parse are silently skipped - callers
+// should run validation first (see validation.ValidateAllowlist) to surface those
+// errors to the user.
+func (a *Allowlist) GetAllowlists() []allowlist.Allower {
+	routes := allowlist.NewRoutes()
+	for _, entry := range a.SkipAuthRoutes {
+		method, pattern := allowlist.ParseRoute(entry)
+		if regex, err := regexp.Compile(pattern); err == nil {
+			routes.AddRoute(method, regex)
+		}
+	}
+	for _, pattern := range a.SkipAuthRegex {
+		if regex, err := regexp.Compile(pattern); err == nil {
+			routes.AddRoute("", regex)
+		}
+	}
+	if a.SkipAuthPreflight {
+		routes.AddRoute("OPTIONS", regexp.MustCompile(".*"))
+	}
+
+	trustedProxies := parseTrustedProxies(a.TrustedIPsTrustedProxies)
+	ips := allowlist.NewIPs(allowlist.NewClientIPResolver(a.TrustedIPsHeader, trustedProxies))
+	for _, entry := range a.TrustedIPs {
+		if ipNet, err := allowlist.ParseIPNet(entry); err == nil {
+			ips.AddIPNet(ipNet)
+		}
+	}
+
+	rewrites := allowlist.NewRewrites()
+	seen := map[string]bool{}
+	for _, entry := range a.SkipAuthRewrites {
+		if seen[entry] {
+			continue
+		}
+		seen[entry] = true
+
+		if strings.HasPrefix(entry, "strip:") {
+			if prefix := strings.TrimPrefix(entry, "strip:"); prefix != "" {
+				rewrites.AddStripRewrite(prefix)
+			}
+			continue
+		}
+		parts := strings.SplitN(entry, "->", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		method, pattern := allowlist.ParseRoute(strings.TrimSpace(parts[0]))
+		if regex, err := regexp.Compile(pattern); err == nil {
+			rewrites.AddMethodRewrite(method, regex, strings.TrimSpace(parts[1]))
+		}
+	}
+
+	headers := allowlist.NewHeaders()
+	for _, entry := range a.SkipAuthHeaders {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if regex, err := regexp.Compile(parts[1]); err == nil {
+			headers.AddHeader(parts[0], regex)
+		}
+	}
+
+	query := allowlist.NewQuery()
+	for _, entry := range a.SkipAuthQuery {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if regex, err := regexp.Compile(parts[1]); err == nil {
+			query.AddQuery(parts[0], regex)
+		}
+	}
+
+	routesFromIPs := allowlist.NewRoutesFromIPs(allowlist.NewClientIPResolver(a.TrustedIPsHeader, trustedProxies))
+	for _, entry := range a.SkipAuthRoutesFromIPs {
+		parts := strings.SplitN(entry, "|", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		ipNet, err := allowlist.ParseIPNet(parts[0])
+		if err != nil {
+			continue
+		}
+		method, pattern := allowlist.ParseRoute(parts[1])
+		if regex, err := regexp.Compile(pattern); err == nil {
+			routesFromIPs.AddRoute(ipNet, method, regex)
+		}
+	}
+
+	return []allowlist.Allower{routes, ips, rewrites, headers, query, routesFromIPs}
+}
+
+// parseTrustedProxies parses the configured trusted-proxy CIDRs, skipping
+// any entry that fails to parse.
+func parseTrustedProxies(entries []string) []*net.IPNet {
+	var trustedProxies []*net.IPNet
+	for _, entry := range entries {
+		if ipNet, err := allowlist.ParseIPNet(entry); err == nil {
+			trustedProxies = append(trustedProxies, ipNet)
+		}
+	}
+	return trustedProxies
+}
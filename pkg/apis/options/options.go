@@ -0,0 +1,10 @@
+// Package options holds the typed configuration for oauth2-proxy, grouped by
+// concern (one struct per feature area) and decoded from a JSON config file.
+package options
+
+// Options holds the configuration for oauth2-proxy.
+type Options struct {
+	// Allowlist holds the rules used to bypass authentication for requests
+	// that should be allowed straight through to the upstream.
+	Allowlist Allowlist `json:"allowlist,omitempty"`
+}
@@ -0,0 +1,243 @@
+package validation
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/oauth2-proxy/oauth2-proxy/pkg/allowlist"
+	"github.com/oauth2-proxy/oauth2-proxy/pkg/apis/options"
+)
+
+// validateAllowlist validates every allowlist rule configured in o and
+// returns any resulting error messages.
+func validateAllowlist(o *options.Options) []string {
+	_, msgs := ValidateAllowlist(o)
+	return msgs
+}
+
+// ValidateAllowlist validates every allowlist rule configured in o and
+// returns the resulting allowlist.Allower set together with any error
+// messages. Callers that reload configuration at runtime (see
+// allowlist.Reloader) should discard the returned Allower set and keep
+// serving the previously validated one whenever msgs is non-empty.
+func ValidateAllowlist(o *options.Options) ([]allowlist.Allower, []string) {
+	msgs := []string{}
+
+	routes := allowlist.NewRoutes()
+	msgs = append(msgs, validateRoutes(&o.Allowlist, routes)...)
+	msgs = append(msgs, validateRegexes(&o.Allowlist, routes)...)
+	msgs = append(msgs, validatePreflight(&o.Allowlist, routes)...)
+
+	trustedProxies, proxyMsgs := validateTrustedProxies(&o.Allowlist)
+	msgs = append(msgs, proxyMsgs...)
+	ips := allowlist.NewIPs(allowlist.NewClientIPResolver(o.Allowlist.TrustedIPsHeader, trustedProxies))
+	msgs = append(msgs, validateTrustedIPs(&o.Allowlist, ips)...)
+
+	rewrites := allowlist.NewRewrites()
+	msgs = append(msgs, validateRewrites(&o.Allowlist, rewrites)...)
+
+	headers := allowlist.NewHeaders()
+	msgs = append(msgs, validateHeaders(&o.Allowlist, headers)...)
+
+	query := allowlist.NewQuery()
+	msgs = append(msgs, validateQuery(&o.Allowlist, query)...)
+
+	routesFromIPs := allowlist.NewRoutesFromIPs(allowlist.NewClientIPResolver(o.Allowlist.TrustedIPsHeader, trustedProxies))
+	msgs = append(msgs, validateRoutesFromIPs(&o.Allowlist, routesFromIPs)...)
+
+	return []allowlist.Allower{routes, ips, rewrites, headers, query, routesFromIPs}, msgs
+}
+
+// validateRoutes parses the configured SkipAuthRoutes entries (optionally
+// prefixed with "METHOD=") into routes.
+func validateRoutes(o *options.Allowlist, routes *allowlist.Routes) []string {
+	msgs := []string{}
+	for _, entry := range o.SkipAuthRoutes {
+		method, pattern := allowlist.ParseRoute(entry)
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("error compiling regex /%s/: %v", pattern, err))
+			continue
+		}
+		routes.AddRoute(method, regex)
+	}
+	return msgs
+}
+
+// validateRegexes parses the configured SkipAuthRegex entries, which match
+// requests of any HTTP method, into routes.
+func validateRegexes(o *options.Allowlist, routes *allowlist.Routes) []string {
+	msgs := []string{}
+	for _, pattern := range o.SkipAuthRegex {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("error compiling regex /%s/: %v", pattern, err))
+			continue
+		}
+		routes.AddRoute("", regex)
+	}
+	return msgs
+}
+
+// validatePreflight allows CORS preflight (OPTIONS) requests to bypass
+// authentication when SkipAuthPreflight is set.
+func validatePreflight(o *options.Allowlist, routes *allowlist.Routes) []string {
+	if o.SkipAuthPreflight {
+		routes.AddRoute("OPTIONS", regexp.MustCompile(".*"))
+	}
+	return []string{}
+}
+
+// validateTrustedProxies parses the configured TrustedIPsTrustedProxies
+// entries, used to decide which proxies may set TrustedIPsHeader.
+func validateTrustedProxies(o *options.Allowlist) ([]*net.IPNet, []string) {
+	msgs := []string{}
+	var trustedProxies []*net.IPNet
+	for _, entry := range o.TrustedIPsTrustedProxies {
+		ipNet, err := allowlist.ParseIPNet(entry)
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("could not parse trusted proxy CIDR (%s)", entry))
+			continue
+		}
+		trustedProxies = append(trustedProxies, ipNet)
+	}
+	return trustedProxies, msgs
+}
+
+// validateTrustedIPs parses the configured TrustedIPs entries into ips, and
+// ensures TrustedIPsHeader is only used alongside at least one trusted proxy
+// CIDR - otherwise any client could spoof the header to bypass TrustedIPs.
+func validateTrustedIPs(o *options.Allowlist, ips *allowlist.IPs) []string {
+	msgs := []string{}
+
+	if o.TrustedIPsHeader != "" && len(o.TrustedIPsTrustedProxies) == 0 {
+		msgs = append(msgs, fmt.Sprintf(
+			"trusted_ips_header (%s) requires at least one trusted_ips_trusted_proxies entry to avoid header spoofing",
+			o.TrustedIPsHeader))
+	}
+
+	for _, entry := range o.TrustedIPs {
+		ipNet, err := allowlist.ParseIPNet(entry)
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("could not parse IP network (%s)", entry))
+			continue
+		}
+		ips.AddIPNet(ipNet)
+	}
+	return msgs
+}
+
+// validateRewrites parses the configured SkipAuthRewrites entries into
+// rewrites. Each entry is either "strip:<prefix>" or
+// "[METHOD=]regex -> replacement"; duplicate entries are skipped rather than
+// registered twice.
+func validateRewrites(o *options.Allowlist, rewrites *allowlist.Rewrites) []string {
+	msgs := []string{}
+	seen := map[string]bool{}
+
+	for _, entry := range o.SkipAuthRewrites {
+		if seen[entry] {
+			continue
+		}
+		seen[entry] = true
+
+		if strings.HasPrefix(entry, "strip:") {
+			prefix := strings.TrimPrefix(entry, "strip:")
+			if prefix == "" {
+				msgs = append(msgs, fmt.Sprintf("invalid rewrite rule %q: strip prefix must not be empty", entry))
+				continue
+			}
+			rewrites.AddStripRewrite(prefix)
+			continue
+		}
+
+		parts := strings.SplitN(entry, "->", 2)
+		if len(parts) != 2 {
+			msgs = append(msgs, fmt.Sprintf(
+				"invalid rewrite rule %q: expected \"[METHOD=]regex -> replacement\" or \"strip:prefix\"", entry))
+			continue
+		}
+		method, pattern := allowlist.ParseRoute(strings.TrimSpace(parts[0]))
+		replacement := strings.TrimSpace(parts[1])
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("error compiling regex /%s/: %v", pattern, err))
+			continue
+		}
+		rewrites.AddMethodRewrite(method, regex, replacement)
+	}
+	return msgs
+}
+
+// validateHeaders parses the configured SkipAuthHeaders entries, each of the
+// form "Header=regex", into headers.
+func validateHeaders(o *options.Allowlist, headers *allowlist.Headers) []string {
+	msgs := []string{}
+	for _, entry := range o.SkipAuthHeaders {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			msgs = append(msgs, fmt.Sprintf("invalid header rule %q: expected \"Header=regex\"", entry))
+			continue
+		}
+		regex, err := regexp.Compile(parts[1])
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("error compiling regex /%s/: %v", parts[1], err))
+			continue
+		}
+		headers.AddHeader(parts[0], regex)
+	}
+	return msgs
+}
+
+// validateQuery parses the configured SkipAuthQuery entries, each of the
+// form "key=regex", into query.
+func validateQuery(o *options.Allowlist, query *allowlist.Query) []string {
+	msgs := []string{}
+	for _, entry := range o.SkipAuthQuery {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			msgs = append(msgs, fmt.Sprintf("invalid query rule %q: expected \"key=regex\"", entry))
+			continue
+		}
+		regex, err := regexp.Compile(parts[1])
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("error compiling regex /%s/: %v", parts[1], err))
+			continue
+		}
+		query.AddQuery(parts[0], regex)
+	}
+	return msgs
+}
+
+// validateRoutesFromIPs parses the configured SkipAuthRoutesFromIPs entries,
+// each of the form "<CIDR>|[METHOD=]regex", into routes. A request must
+// match both the CIDR and the method/regex to be trusted.
+func validateRoutesFromIPs(o *options.Allowlist, routes *allowlist.RoutesFromIPs) []string {
+	msgs := []string{}
+	for _, entry := range o.SkipAuthRoutesFromIPs {
+		parts := strings.SplitN(entry, "|", 2)
+		if len(parts) != 2 {
+			msgs = append(msgs, fmt.Sprintf(
+				"invalid route-from-ips rule %q: expected \"<CIDR>|[METHOD=]regex\"", entry))
+			continue
+		}
+
+		ipNet, err := allowlist.ParseIPNet(parts[0])
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("could not parse IP network (%s)", parts[0]))
+			continue
+		}
+
+		method, pattern := allowlist.ParseRoute(parts[1])
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("error compiling regex /%s/: %v", pattern, err))
+			continue
+		}
+
+		routes.AddRoute(ipNet, method, regex)
+	}
+	return msgs
+}
@@ -24,6 +24,11 @@ func Test_validateAllowlist(t *testing.T) {
 				"10.32.0.1/32",
 				"43.36.201.0/24",
 			},
+			SkipAuthHeaders: []string{"X-Internal-Call=^1$"},
+			SkipAuthQuery:   []string{"health=^1$"},
+			SkipAuthRoutesFromIPs: []string{
+				"10.0.0.0/8|GET=^/metrics$",
+			},
 		},
 	}
 	assert.Equal(t, []string{}, validateAllowlist(opts))
@@ -77,6 +82,50 @@ func Test_validateAllowlist(t *testing.T) {
 	}
 	assert.True(t, isTrusted(ipReq))
 
+	// Trusted via SkipAuthHeaders
+	headerReq := &http.Request{
+		Method:     "POST",
+		URL:        &url.URL{Path: "/super/secret/route"},
+		RemoteAddr: "1.2.3.4:443",
+		Header:     http.Header{"X-Internal-Call": []string{"1"}},
+	}
+	assert.True(t, isTrusted(headerReq))
+
+	// Trusted via SkipAuthQuery
+	queryReq := &http.Request{
+		Method:     "GET",
+		URL:        &url.URL{Path: "/super/secret/route", RawQuery: "health=1"},
+		RemoteAddr: "1.2.3.4:443",
+	}
+	assert.True(t, isTrusted(queryReq))
+
+	// Trusted via SkipAuthRoutesFromIPs: both the path and the source IP
+	// must match.
+	routeFromIPsReq := &http.Request{
+		Method:     "GET",
+		URL:        &url.URL{Path: "/metrics"},
+		RemoteAddr: "10.1.2.3:443",
+	}
+	assert.True(t, isTrusted(routeFromIPsReq))
+
+	// Matching only the path (wrong source IP) is not trusted via
+	// SkipAuthRoutesFromIPs.
+	routeFromIPsWrongIPReq := &http.Request{
+		Method:     "GET",
+		URL:        &url.URL{Path: "/metrics"},
+		RemoteAddr: "1.2.3.4:443",
+	}
+	assert.False(t, isTrusted(routeFromIPsWrongIPReq))
+
+	// Matching only the source IP (wrong path) is not trusted via
+	// SkipAuthRoutesFromIPs.
+	routeFromIPsWrongPathReq := &http.Request{
+		Method:     "GET",
+		URL:        &url.URL{Path: "/super/secret/route"},
+		RemoteAddr: "10.1.2.3:443",
+	}
+	assert.False(t, isTrusted(routeFromIPsWrongPathReq))
+
 	// Not trusted
 	authReq := &http.Request{
 		Method: "POST",
@@ -88,6 +137,76 @@ func Test_validateAllowlist(t *testing.T) {
 	assert.False(t, isTrusted(authReq))
 }
 
+func Test_validateTrustedIPsHeader(t *testing.T) {
+	testCases := map[string]struct {
+		TrustedIPs     []string
+		Header         string
+		TrustedProxies []string
+		RemoteAddr     string
+		ForwardedFor   string
+		Expected       []string
+		ExpectTrusted  bool
+	}{
+		"Header configured without a trusted proxy is rejected": {
+			TrustedIPs:     []string{"203.0.113.7"},
+			Header:         "X-Forwarded-For",
+			TrustedProxies: []string{},
+			Expected: []string{
+				"trusted_ips_header (X-Forwarded-For) requires at least one trusted_ips_trusted_proxies entry to avoid header spoofing",
+			},
+		},
+		"Legitimate forwarded chain from a trusted proxy is honored": {
+			TrustedIPs:     []string{"203.0.113.7"},
+			Header:         "X-Forwarded-For",
+			TrustedProxies: []string{"10.1.0.0/24"},
+			RemoteAddr:     "10.1.0.9:443",
+			ForwardedFor:   "203.0.113.7, 10.1.0.9",
+			Expected:       []string{},
+			ExpectTrusted:  true,
+		},
+		"Spoofed forwarded header from an untrusted peer is ignored": {
+			TrustedIPs:     []string{"203.0.113.7"},
+			Header:         "X-Forwarded-For",
+			TrustedProxies: []string{"10.1.0.0/24"},
+			RemoteAddr:     "1.2.3.4:443",
+			ForwardedFor:   "203.0.113.7",
+			Expected:       []string{},
+			ExpectTrusted:  false,
+		},
+		"Malformed trusted proxy entry is rejected": {
+			TrustedIPs:     []string{"203.0.113.7"},
+			Header:         "X-Forwarded-For",
+			TrustedProxies: []string{"not-a-cidr"},
+			Expected: []string{
+				"could not parse trusted proxy CIDR (not-a-cidr)",
+			},
+		},
+	}
+
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			opts := &options.Allowlist{
+				TrustedIPs:               tc.TrustedIPs,
+				TrustedIPsHeader:         tc.Header,
+				TrustedIPsTrustedProxies: tc.TrustedProxies,
+			}
+			trustedProxies, proxyMsgs := validateTrustedProxies(opts)
+			ips := allowlist.NewIPs(allowlist.NewClientIPResolver(tc.Header, trustedProxies))
+			msgs := append(proxyMsgs, validateTrustedIPs(opts, ips)...)
+			assert.Equal(t, tc.Expected, msgs)
+
+			if len(msgs) == 0 && tc.RemoteAddr != "" {
+				req := &http.Request{
+					RemoteAddr: tc.RemoteAddr,
+					Header:     http.Header{},
+				}
+				req.Header.Set("X-Forwarded-For", tc.ForwardedFor)
+				assert.Equal(t, tc.ExpectTrusted, ips.IsTrusted(req))
+			}
+		})
+	}
+}
+
 func Test_validateRoutes(t *testing.T) {
 	testCases := map[string]struct {
 		Regexes  []string
@@ -152,6 +271,246 @@ func Test_validateRoutes(t *testing.T) {
 	}
 }
 
+func Test_validateRoutesFromIPs(t *testing.T) {
+	testCases := map[string]struct {
+		Rules    []string
+		Expected []string
+	}{
+		"Valid CIDR-scoped route": {
+			Rules:    []string{"10.0.0.0/8|GET=^/metrics$"},
+			Expected: []string{},
+		},
+		"Missing '|' separator is invalid syntax": {
+			Rules: []string{"10.0.0.0/8 GET=^/metrics$"},
+			Expected: []string{
+				`invalid route-from-ips rule "10.0.0.0/8 GET=^/metrics$": expected "<CIDR>|[METHOD=]regex"`,
+			},
+		},
+		"Bad CIDR does not parse": {
+			Rules: []string{"not-a-cidr|GET=^/metrics$"},
+			Expected: []string{
+				"could not parse IP network (not-a-cidr)",
+			},
+		},
+		"Bad regex does not compile": {
+			Rules: []string{"10.0.0.0/8|GET=^/(metrics$"},
+			Expected: []string{
+				"error compiling regex /^/(metrics$/: error parsing regexp: missing closing ): `^/(metrics$`",
+			},
+		},
+	}
+
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			routes := allowlist.NewRoutesFromIPs(nil)
+			opts := &options.Allowlist{SkipAuthRoutesFromIPs: tc.Rules}
+			msgs := validateRoutesFromIPs(opts, routes)
+			assert.Equal(t, tc.Expected, msgs)
+		})
+	}
+
+	t.Run("Requires both the path and the source IP to match", func(t *testing.T) {
+		routes := allowlist.NewRoutesFromIPs(nil)
+		opts := &options.Allowlist{SkipAuthRoutesFromIPs: []string{"10.0.0.0/8|GET=^/metrics$"}}
+		assert.Equal(t, []string{}, validateRoutesFromIPs(opts, routes))
+
+		both := &http.Request{Method: "GET", URL: &url.URL{Path: "/metrics"}, RemoteAddr: "10.1.2.3:443"}
+		assert.True(t, routes.IsTrusted(both))
+
+		onlyPath := &http.Request{Method: "GET", URL: &url.URL{Path: "/metrics"}, RemoteAddr: "1.2.3.4:443"}
+		assert.False(t, routes.IsTrusted(onlyPath))
+
+		onlyIP := &http.Request{Method: "GET", URL: &url.URL{Path: "/other"}, RemoteAddr: "10.1.2.3:443"}
+		assert.False(t, routes.IsTrusted(onlyIP))
+	})
+}
+
+func Test_validateRewrites(t *testing.T) {
+	testCases := map[string]struct {
+		Rewrites []string
+		Expected []string
+	}{
+		"Method-prefixed regex rewrite": {
+			Rewrites: []string{"POST=/api/v1/foo -> /foo"},
+			Expected: []string{},
+		},
+		"Strip-prefix rewrite": {
+			Rewrites: []string{"strip:/public/"},
+			Expected: []string{},
+		},
+		"Duplicate rules are deduped": {
+			Rewrites: []string{
+				"POST=/api/v1/foo -> /foo",
+				"POST=/api/v1/foo -> /foo",
+				"strip:/public/",
+				"strip:/public/",
+			},
+			Expected: []string{},
+		},
+		"Missing arrow or strip prefix is invalid syntax": {
+			Rewrites: []string{"POST=/api/v1/foo"},
+			Expected: []string{
+				`invalid rewrite rule "POST=/api/v1/foo": expected "[METHOD=]regex -> replacement" or "strip:prefix"`,
+			},
+		},
+		"Empty strip prefix is invalid syntax": {
+			Rewrites: []string{"strip:"},
+			Expected: []string{
+				`invalid rewrite rule "strip:": strip prefix must not be empty`,
+			},
+		},
+		"Bad regex does not compile": {
+			Rewrites: []string{"POST=/(foo -> /foo"},
+			Expected: []string{
+				"error compiling regex //(foo/: error parsing regexp: missing closing ): `/(foo`",
+			},
+		},
+	}
+
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			rewrites := allowlist.NewRewrites()
+			opts := &options.Allowlist{
+				SkipAuthRewrites: tc.Rewrites,
+			}
+			msgs := validateRewrites(opts, rewrites)
+			assert.Equal(t, tc.Expected, msgs)
+		})
+	}
+
+	t.Run("Method rewrite replaces the path and preserves the original", func(t *testing.T) {
+		rewrites := allowlist.NewRewrites()
+		opts := &options.Allowlist{
+			SkipAuthRewrites: []string{"POST=/api/v1/foo -> /foo"},
+		}
+		assert.Equal(t, []string{}, validateRewrites(opts, rewrites))
+
+		req := &http.Request{
+			Method: "POST",
+			URL:    &url.URL{Path: "/api/v1/foo"},
+			Header: http.Header{},
+		}
+		assert.True(t, rewrites.IsTrusted(req))
+		rewrites.Rewrite(req)
+		assert.Equal(t, "/foo", req.URL.Path)
+		assert.Equal(t, "/api/v1/foo", req.Header.Get(allowlist.RewritePathHeader))
+	})
+
+	t.Run("Strip rewrite removes the prefix and preserves the original", func(t *testing.T) {
+		rewrites := allowlist.NewRewrites()
+		opts := &options.Allowlist{
+			SkipAuthRewrites: []string{"strip:/public"},
+		}
+		assert.Equal(t, []string{}, validateRewrites(opts, rewrites))
+
+		req := &http.Request{
+			Method: "GET",
+			URL:    &url.URL{Path: "/public/assets/app.js"},
+			Header: http.Header{},
+		}
+		assert.True(t, rewrites.IsTrusted(req))
+		rewrites.Rewrite(req)
+		assert.Equal(t, "/assets/app.js", req.URL.Path)
+		assert.Equal(t, "/public/assets/app.js", req.Header.Get(allowlist.RewritePathHeader))
+	})
+}
+
+func Test_validateHeaders(t *testing.T) {
+	testCases := map[string]struct {
+		Rules    []string
+		Expected []string
+	}{
+		"Valid header rule": {
+			Rules:    []string{"X-Internal-Call=^1$"},
+			Expected: []string{},
+		},
+		"Missing '=' is invalid syntax": {
+			Rules: []string{"X-Internal-Call"},
+			Expected: []string{
+				`invalid header rule "X-Internal-Call": expected "Header=regex"`,
+			},
+		},
+		"Bad regex does not compile": {
+			Rules: []string{"X-Internal-Call=(1"},
+			Expected: []string{
+				"error compiling regex /(1/: error parsing regexp: missing closing ): `(1`",
+			},
+		},
+	}
+
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			headers := allowlist.NewHeaders()
+			opts := &options.Allowlist{SkipAuthHeaders: tc.Rules}
+			msgs := validateHeaders(opts, headers)
+			assert.Equal(t, tc.Expected, msgs)
+		})
+	}
+
+	t.Run("Only trips on the configured header and value", func(t *testing.T) {
+		headers := allowlist.NewHeaders()
+		opts := &options.Allowlist{SkipAuthHeaders: []string{"X-Internal-Call=^1$"}}
+		assert.Equal(t, []string{}, validateHeaders(opts, headers))
+
+		trusted := &http.Request{Header: http.Header{"X-Internal-Call": []string{"1"}}}
+		assert.True(t, headers.IsTrusted(trusted))
+
+		wrongValue := &http.Request{Header: http.Header{"X-Internal-Call": []string{"0"}}}
+		assert.False(t, headers.IsTrusted(wrongValue))
+
+		missing := &http.Request{Header: http.Header{}}
+		assert.False(t, headers.IsTrusted(missing))
+	})
+}
+
+func Test_validateQuery(t *testing.T) {
+	testCases := map[string]struct {
+		Rules    []string
+		Expected []string
+	}{
+		"Valid query rule": {
+			Rules:    []string{"health=^1$"},
+			Expected: []string{},
+		},
+		"Missing '=' is invalid syntax": {
+			Rules: []string{"health"},
+			Expected: []string{
+				`invalid query rule "health": expected "key=regex"`,
+			},
+		},
+		"Bad regex does not compile": {
+			Rules: []string{"health=(1"},
+			Expected: []string{
+				"error compiling regex /(1/: error parsing regexp: missing closing ): `(1`",
+			},
+		},
+	}
+
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			query := allowlist.NewQuery()
+			opts := &options.Allowlist{SkipAuthQuery: tc.Rules}
+			msgs := validateQuery(opts, query)
+			assert.Equal(t, tc.Expected, msgs)
+		})
+	}
+
+	t.Run("Only trips on the configured key and value", func(t *testing.T) {
+		query := allowlist.NewQuery()
+		opts := &options.Allowlist{SkipAuthQuery: []string{"health=^1$"}}
+		assert.Equal(t, []string{}, validateQuery(opts, query))
+
+		trusted := &http.Request{URL: &url.URL{RawQuery: "health=1"}}
+		assert.True(t, query.IsTrusted(trusted))
+
+		wrongValue := &http.Request{URL: &url.URL{RawQuery: "health=0"}}
+		assert.False(t, query.IsTrusted(wrongValue))
+
+		missing := &http.Request{URL: &url.URL{RawQuery: "foo=1"}}
+		assert.False(t, query.IsTrusted(missing))
+	})
+}
+
 func Test_validateRegexes(t *testing.T) {
 	testCases := map[string]struct {
 		Regexes  []string
@@ -298,3 +657,40 @@ func Test_validateTrustedIPs(t *testing.T) {
 		})
 	}
 }
+
+func Test_ValidateAllowlistReload(t *testing.T) {
+	goodOpts := &options.Options{
+		Allowlist: options.Allowlist{SkipAuthRoutes: []string{"/v1"}},
+	}
+	allowers, msgs := ValidateAllowlist(goodOpts)
+	assert.Equal(t, []string{}, msgs)
+
+	reloader := allowlist.NewReloader(allowers)
+	req := &http.Request{Method: "GET", URL: &url.URL{Path: "/v1"}}
+	assert.True(t, reloader.IsTrusted(req))
+
+	// A reload with a bad regex must be rejected, leaving the previously
+	// validated allowlist in place.
+	badOpts := &options.Options{
+		Allowlist: options.Allowlist{SkipAuthRoutes: []string{"/(bad"}},
+	}
+	newAllowers, badMsgs := ValidateAllowlist(badOpts)
+	assert.Equal(t, []string{
+		"error compiling regex //(bad/: error parsing regexp: missing closing ): `/(bad`",
+	}, badMsgs)
+	if len(badMsgs) == 0 {
+		reloader.Store(newAllowers)
+	}
+	assert.True(t, reloader.IsTrusted(req))
+
+	// A subsequent good reload does take effect.
+	replacementOpts := &options.Options{
+		Allowlist: options.Allowlist{SkipAuthRoutes: []string{"/v2"}},
+	}
+	replacementAllowers, replacementMsgs := ValidateAllowlist(replacementOpts)
+	assert.Equal(t, []string{}, replacementMsgs)
+	reloader.Store(replacementAllowers)
+
+	assert.False(t, reloader.IsTrusted(req))
+	assert.True(t, reloader.IsTrusted(&http.Request{Method: "GET", URL: &url.URL{Path: "/v2"}}))
+}